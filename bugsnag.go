@@ -0,0 +1,40 @@
+package ghealth
+
+import (
+	"github.com/bugsnag/bugsnag-go"
+	"github.com/gocraft/health"
+)
+
+// bugsnagSink is a health.Sink that forwards job errors (including
+// recovered panics reported via job.EventErr) to Bugsnag.
+type bugsnagSink struct{}
+
+// WithBugsnag configures the Bugsnag client with apiKey and adds a
+// sink that notifies Bugsnag of every EmitEventErr, e.g. the "panic"
+// event emitted by Health()'s recovery.
+func WithBugsnag(apiKey string) Option {
+	return func(stream *health.Stream) {
+		bugsnag.Configure(bugsnag.Configuration{APIKey: apiKey})
+		stream.AddSink(&bugsnagSink{})
+	}
+}
+
+func (s *bugsnagSink) EmitEvent(job string, event string, kvs map[string]string) {}
+
+func (s *bugsnagSink) EmitEventErr(job string, event string, inputErr error, kvs map[string]string) {
+	bugsnag.Notify(inputErr, bugsnag.MetaData{
+		"health": {
+			"job":   job,
+			"event": event,
+			"kvs":   kvs,
+		},
+	})
+}
+
+func (s *bugsnagSink) EmitTiming(job string, event string, nanoseconds int64, kvs map[string]string) {
+}
+
+func (s *bugsnagSink) EmitGauge(job string, event string, value float64, kvs map[string]string) {}
+
+func (s *bugsnagSink) EmitComplete(job string, status health.CompletionStatus, nanoseconds int64, kvs map[string]string) {
+}
@@ -0,0 +1,71 @@
+package ghealth
+
+import "sync"
+
+// defaultMaxJobNames bounds how many distinct job names Health() will
+// report before collapsing the rest into overflowJobName, unless
+// overridden with WithMaxJobNames.
+const defaultMaxJobNames = 500
+
+// overflowJobName is the job name used once a stream's cardinality
+// cap has been hit, so a runaway set of job names (e.g. from a buggy
+// JobNamer) can't take down the sink instead of just the metric.
+const overflowJobName = "overflow"
+
+// jobNameCap bounds the number of distinct job names a Health()
+// instance will report. Names already being tracked are always
+// allowed through; once the cap is hit, new names are collapsed into
+// overflowJobName and counted instead. This is a hard cap, not an
+// LRU: it doesn't evict tracked names to make room for new ones, so
+// picking a cap comfortably above a service's real route count
+// matters more than picking a "recent" window.
+type jobNameCap struct {
+	mu       sync.Mutex
+	cap      int
+	names    map[string]struct{}
+	overflow int64
+}
+
+func newJobNameCap(capacity int) *jobNameCap {
+	return &jobNameCap{
+		cap:   capacity,
+		names: make(map[string]struct{}),
+	}
+}
+
+// Allow returns the job name to actually use: name itself if it's
+// already tracked or there's still room for it, or overflowJobName if
+// the cardinality cap has been reached.
+func (c *jobNameCap) Allow(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.names[name]; ok {
+		return name
+	}
+
+	if c.cap <= 0 || len(c.names) < c.cap {
+		c.names[name] = struct{}{}
+		return name
+	}
+
+	c.overflow++
+	return overflowJobName
+}
+
+// Overflow returns how many requests have been collapsed into
+// overflowJobName since creation.
+func (c *jobNameCap) Overflow() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.overflow
+}
+
+// WithMaxJobNames bounds how many distinct job names Health() will
+// report before collapsing the rest into a shared "overflow" bucket.
+// Defaults to 500; pass 0 to disable the cap.
+func WithMaxJobNames(n int) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.maxJobNames = n
+	}
+}
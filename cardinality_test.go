@@ -0,0 +1,42 @@
+package ghealth
+
+import "testing"
+
+func TestJobNameCapAllow(t *testing.T) {
+	c := newJobNameCap(2)
+
+	if got := c.Allow("a"); got != "a" {
+		t.Fatalf("Allow(a) = %q, want %q", got, "a")
+	}
+	if got := c.Allow("b"); got != "b" {
+		t.Fatalf("Allow(b) = %q, want %q", got, "b")
+	}
+	// Already-tracked names stay allowed past the cap.
+	if got := c.Allow("a"); got != "a" {
+		t.Fatalf("Allow(a) (repeat) = %q, want %q", got, "a")
+	}
+	// A third distinct name overflows.
+	if got := c.Allow("c"); got != overflowJobName {
+		t.Fatalf("Allow(c) = %q, want %q", got, overflowJobName)
+	}
+	if got := c.Overflow(); got != 1 {
+		t.Fatalf("Overflow() = %d, want 1", got)
+	}
+	// Overflowing again keeps counting.
+	c.Allow("d")
+	if got := c.Overflow(); got != 2 {
+		t.Fatalf("Overflow() = %d, want 2", got)
+	}
+}
+
+func TestJobNameCapZeroDisablesCap(t *testing.T) {
+	c := newJobNameCap(0)
+	for _, name := range []string{"a", "b", "c"} {
+		if got := c.Allow(name); got != name {
+			t.Fatalf("Allow(%s) = %q, want %q (cap disabled)", name, got, name)
+		}
+	}
+	if got := c.Overflow(); got != 0 {
+		t.Fatalf("Overflow() = %d, want 0", got)
+	}
+}
@@ -2,11 +2,18 @@
 Package ghealth provides a Gin middleware
 to gocraft/health performance monitoring toolkit.
 
-By default it creates StatsD sink, falling back to stdout if
-error happened or StatsD server was not provided.
-
-Recovery is supported and panics are sent as general errors
-with request's URI.
+Sinks are composed declaratively with a functional-options
+NewStreamWithOptions: StatsD, stdout/any io.Writer, JSON polling (for
+healthd), Syslog, Bugsnag and a gRPC health.v1 Health service can all
+be mixed and matched. NewStream(statsd, appname, serversink) is kept
+as a deprecated thin shim over it for one release.
+
+Health() creates a *health.Job for every request that its sampling
+options report (by default, every request), named after the matched
+route, and completes it with a status derived from the response.
+Recovery is supported; panics on reported requests are reported as
+job errors, and every panic is surfaced as a 500 if nothing was
+written yet.
 
 Example
 
@@ -14,16 +21,11 @@ Example
 		// Standard router initialization
 		router := gin.Default()
 
-		// First, you need to create a new stream...
-
-		// Simplest sink, stdout only
-		hstream := ghealth.NewStream("", "", "")
-
-		// STDOUT and JSON sinks; creates independent http server on port 5020
-		hstream := ghealth.NewStream("", "", "127.0.0.1:5020")
-
-		// StatsD and JSON sinks
-		hstream := ghealth.NewStream("statsd.server:5000", "yourappname", "127.0.0.1:5020")
+		// Compose whichever sinks you need...
+		hstream := ghealth.NewStreamWithOptions(
+			ghealth.WithStatsD("statsd.server:5000", "yourappname"),
+			ghealth.WithJSONPolling("127.0.0.1:5020"),
+		)
 
 		// It's a standard *health.Stream, so you can do anything you want!
 		hstream.AddSink(&health.WriterSink{os.Stdout})
@@ -34,8 +36,8 @@ Example
 	}
 
 	var someRoute gin.HandlerFunc = func(c *gin.Context) {
-		// Retrieve a job object
-		job := ghealth.Job(c, "some_route")
+		// Retrieve the job created by Health() for this request
+		job := ghealth.Job(c)
 
 		// It's a *health.Job, read health godoc for more info :)
 		job.Event("some_event")
@@ -48,11 +50,9 @@ should be, too.
 package ghealth
 
 import (
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gocraft/health"
 	"net/http"
-	"os"
 	"time"
 )
 
@@ -61,67 +61,161 @@ const (
 	defaultJobKey    = "github.com/utrack/gin-health|job"
 )
 
-// NewStream initializes health sink to statsd using supplied
-// statsd address (IP:PORT) and appname.
-// Falls back to stdout if none supplied.
-// Also creates Json sink for healthd at supplied address
-// (serversink) if not empty.
+// Use this method to inject the middleware and recovery.
 //
-// statsd: StatsD address and port.
+// Health names every request with the configured JobNamer (by default
+// "<method> <route template>", e.g. "GET /users/:id") and completes
+// its job once the handlers are done running, with a status derived
+// from the response's status code and from c.Errors: 2xx/3xx results
+// in health.Success, 4xx in health.ValidationError, 5xx or a panic in
+// health.Error, and a cancelled client context in health.Junk. Only
+// requests that WithSampleRate/WithRouteAllowList/WithRouteDenyList
+// decide to report actually allocate a *health.Job - see Sampler -
+// though calling Job(c) from a handler allocates (and reports) one
+// regardless. WithTailSampling needs an accurate request duration to
+// decide, so a configured TailSampler makes every request pre-
+// allocate its job up front, trading away that allocation saving for
+// timing accuracy.
 //
-// appname Application name for StatsD.
+// A recovered panic is converted to an error (see WithPanicConverter)
+// and surfaced as a 500 response only if nothing was written yet. If
+// the request is reported, the error is also emitted as a "panic"
+// event carrying a stack trace plus request metadata (see
+// WithKvExtractor); a request left unreported by sampling emits no
+// panic event.
 //
-// serversink: Bind address for Json sink, empty if not needed.
-func NewStream(statsd string, appname string, serversink string) *health.Stream {
-	var stream = health.NewStream()
-
-	if statsd != "" {
-		statsdSink, err := health.NewStatsDSink(statsd, appname)
-		if err != nil {
-			fmt.Println("HEALTH: Adding stdout health sink...")
-			stream.AddSink(&health.WriterSink{os.Stdout})
-			stream.EventErr("new_statsd_sink", err)
-		} else {
-			fmt.Println("HEALTH: Adding statsd health sink...")
-			stream.AddSink(statsdSink)
-		}
-	} else {
-		fmt.Println("HEALTH: Adding stdout health sink...")
-		stream.AddSink(&health.WriterSink{os.Stdout})
+// Job names beyond WithMaxJobNames are collapsed into a shared
+// "overflow" bucket.
+func Health(stream *health.Stream, opts ...HealthOption) gin.HandlerFunc {
+	cfg := defaultHealthConfig()
+	for _, opt := range opts {
+		opt(cfg)
 	}
-
-	if serversink != "" {
-		sink := health.NewJsonPollingSink(time.Minute, time.Minute*5)
-		stream.AddSink(sink)
-		sink.StartServer(serversink)
-		fmt.Println("HEALTH: Adding json health sink...")
+	if cfg.kvExtractor == nil {
+		cfg.kvExtractor = defaultKvExtractor(cfg.requestIDHeader)
 	}
-	return stream
-}
+	cardinality := newJobNameCap(cfg.maxJobNames)
 
-// Use this method to inject the middleware and recovery.
-func Health(stream *health.Stream) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		start := time.Now()
+		name := cfg.jobNamer.JobName(c)
+		if allowed := cardinality.Allow(name); allowed != name {
+			name = allowed
+			stream.NewJob(overflowJobName).GaugeKv("dropped_names", float64(cardinality.Overflow()), nil)
+		}
+
+		reported := allSamplersAgree(cfg.samplers, c)
+
+		job := &lazyJob{stream: stream, name: name}
+		if reported || cfg.tailSampler != nil {
+			// A configured TailSampler might still decide to report
+			// this request, and by then it's too late to capture an
+			// accurate start time - resolve the job now so a later
+			// Complete() reflects the real request duration instead
+			// of a near-zero one.
+			job.resolve()
+		}
+		c.Set(defaultStreamKey, stream)
+		c.Set(defaultJobKey, &requestJob{lazy: job, reported: &reported})
+
 		defer func() {
+			var panicErr error
 			if rval := recover(); rval != nil {
-				stream.EventErr(fmt.Sprintf("Panic at %v", c.Request.RequestURI), rval.(error))
+				panicErr = cfg.panicConverter(rval)
+			}
+
+			status := completionStatus(c)
+			if panicErr != nil {
+				status = health.Error
+			}
+
+			if !reported && cfg.tailSampler != nil {
+				reported = cfg.tailSampler.SampleTail(status, time.Since(start))
+			}
+
+			if reported {
+				if panicErr != nil {
+					job.resolve().EventErrKv("panic", panicErr, cfg.kvExtractor(c, panicErr))
+				}
+				job.resolve().Complete(status)
+			}
+
+			if panicErr != nil && !c.Writer.Written() {
 				c.Writer.WriteHeader(http.StatusInternalServerError)
 			}
 		}()
-		c.Set(defaultStreamKey, stream)
+
 		c.Next()
 	}
 }
 
-// Job creates a new job with given name.
+// routeName builds a stable job name out of the request's method and
+// its matched route template, e.g. "GET /users/:id". When no route
+// matched, the request's URL path stands in for the template.
+func routeName(c *gin.Context) string {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	return c.Request.Method + " " + route
+}
+
+// allSamplersAgree reports whether every configured Sampler agrees to
+// report this request; with no samplers configured, everything is
+// reported.
+func allSamplersAgree(samplers []Sampler, c *gin.Context) bool {
+	for _, s := range samplers {
+		if !s.Sample(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// completionStatus derives a health.CompletionStatus from the
+// response written so far and from any errors attached to c.
+func completionStatus(c *gin.Context) health.CompletionStatus {
+	if c.Request.Context().Err() != nil {
+		return health.Junk
+	}
+
+	switch status := c.Writer.Status(); {
+	case status >= http.StatusInternalServerError:
+		return health.Error
+	case status >= http.StatusBadRequest:
+		return health.ValidationError
+	case len(c.Errors) > 0:
+		return health.Error
+	default:
+		return health.Success
+	}
+}
+
+// requestJob is what's stashed in the gin.Context: the lazily
+// allocated job for this request plus a pointer back into Health()'s
+// `reported` variable. A handler calling Job(c) forces the
+// allocation, and must also force reported = true - otherwise Health()
+// would never call Complete() on a job a handler has already started
+// emitting events on.
+type requestJob struct {
+	lazy     *lazyJob
+	reported *bool
+}
+
+func (r *requestJob) get() *health.Job {
+	*r.reported = true
+	return r.lazy.resolve()
+}
+
+// Job returns the *health.Job for the current request, allocating it
+// (and marking the request as reported, so Health() completes it
+// once the handlers are done) if Health() hadn't needed to yet, e.g.
+// the request was sampled out, for adding custom events or timings
+// from within a handler.
 //
 // c: current Gin context.
-//
-// name: Job's name.
-func Job(c *gin.Context, name string) *health.Job {
-	job := c.MustGet(defaultStreamKey).(*health.Stream).NewJob(name)
-	c.Set(defaultJobKey, time.Now())
-	return job
+func Job(c *gin.Context) *health.Job {
+	return c.MustGet(defaultJobKey).(*requestJob).get()
 }
 
 // TimeSince is a little helper over time.Time.TimeSince
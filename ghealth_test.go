@@ -0,0 +1,123 @@
+package ghealth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocraft/health"
+)
+
+var errTest = errors.New("test error")
+
+func TestCompletionStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		cErrors    bool
+		cancelled  bool
+		want       health.CompletionStatus
+	}{
+		{"success", http.StatusOK, false, false, health.Success},
+		{"redirect", http.StatusFound, false, false, health.Success},
+		{"client error", http.StatusBadRequest, false, false, health.ValidationError},
+		{"server error", http.StatusInternalServerError, false, false, health.Error},
+		{"attached error", http.StatusOK, true, false, health.Error},
+		{"cancelled context wins", http.StatusInternalServerError, false, true, health.Junk},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.cancelled {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+			c.Request = req
+			c.Writer.WriteHeader(tt.statusCode)
+			if tt.cErrors {
+				c.Error(errTest)
+			}
+
+			if got := completionStatus(c); got != tt.want {
+				t.Errorf("completionStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeSink records EmitComplete calls so tests can assert on reported
+// job names, statuses and durations without a real sink.
+type fakeSink struct {
+	completes []completeCall
+}
+
+type completeCall struct {
+	job         string
+	status      health.CompletionStatus
+	nanoseconds int64
+}
+
+func (s *fakeSink) EmitEvent(job, event string, kvs map[string]string)                    {}
+func (s *fakeSink) EmitEventErr(job, event string, err error, kvs map[string]string)      {}
+func (s *fakeSink) EmitTiming(job, event string, nanoseconds int64, kvs map[string]string) {}
+func (s *fakeSink) EmitGauge(job, event string, value float64, kvs map[string]string)      {}
+
+func (s *fakeSink) EmitComplete(job string, status health.CompletionStatus, nanoseconds int64, kvs map[string]string) {
+	s.completes = append(s.completes, completeCall{job, status, nanoseconds})
+}
+
+func TestHealthTailSamplingCapturesRealDuration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &fakeSink{}
+	stream := health.NewStream()
+	stream.AddSink(sink)
+
+	const sleep = 20 * time.Millisecond
+	router := gin.New()
+	router.Use(Health(stream,
+		WithSampleRate(0), // never sampled up front
+		WithTailSampling(false, time.Millisecond), // report anything slower than 1ms
+	))
+	router.GET("/slow", func(c *gin.Context) {
+		time.Sleep(sleep)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if len(sink.completes) != 1 {
+		t.Fatalf("got %d completions, want 1", len(sink.completes))
+	}
+	if got := sink.completes[0].nanoseconds; got < sleep.Nanoseconds() {
+		t.Errorf("Complete() duration = %dns, want at least %dns (job allocated too late)", got, sleep.Nanoseconds())
+	}
+}
+
+func TestHealthJobCForcesCompletion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &fakeSink{}
+	stream := health.NewStream()
+	stream.AddSink(sink)
+
+	router := gin.New()
+	router.Use(Health(stream, WithSampleRate(0))) // never sampled
+	router.GET("/custom", func(c *gin.Context) {
+		Job(c).Event("custom")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/custom", nil))
+
+	if len(sink.completes) != 1 {
+		t.Fatalf("got %d completions, want 1 (Job(c) should force this request to be reported)", len(sink.completes))
+	}
+}
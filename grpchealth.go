@@ -0,0 +1,189 @@
+package ghealth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gocraft/health"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// errorRateWindow is how far back grpcHealthSink looks when deciding
+// whether the service is SERVING.
+const errorRateWindow = time.Minute
+
+// bucketWidth is the granularity of the rolling window: each bucket
+// holds the counts for one second, so the window is tracked as
+// errorRateWindow/bucketWidth fixed buckets instead of an
+// ever-growing list of completions.
+const bucketWidth = time.Second
+
+const numBuckets = int(errorRateWindow / bucketWidth)
+
+// unhealthyErrorRate is the error rate above which the gRPC health
+// check reports NOT_SERVING.
+const unhealthyErrorRate = 0.5
+
+// bucket holds the completion counts for one bucketWidth-wide second,
+// identified by secondsSinceEpoch so a stale bucket can be detected
+// and reset in O(1) instead of scanned out of a slice.
+type bucket struct {
+	secondsSinceEpoch int64
+	total             int64
+	errors            int64
+}
+
+// grpcHealthSink is a health.Sink that keeps a fixed-size rolling
+// window of recent completions and backs a grpc.health.v1.Health
+// service off of it, so the same *health.Stream can serve as a
+// mesh/k8s gRPC readiness or liveness probe. Both EmitComplete and
+// status() do O(1)/O(numBuckets) work regardless of request volume.
+type grpcHealthSink struct {
+	mu      sync.Mutex
+	buckets [numBuckets]bucket
+
+	watchersMu    sync.Mutex
+	watchers      map[chan struct{}]struct{}
+	lastBroadcast grpc_health_v1.HealthCheckResponse_ServingStatus
+}
+
+func newGRPCHealthSink() *grpcHealthSink {
+	return &grpcHealthSink{
+		watchers:      make(map[chan struct{}]struct{}),
+		lastBroadcast: grpc_health_v1.HealthCheckResponse_SERVING,
+	}
+}
+
+// WithGRPCHealth registers a grpc.health.v1.Health service on server,
+// backed by the stream's recent error/success rates: the service
+// reports NOT_SERVING once errors make up more than half of the
+// completions seen in the last minute.
+func WithGRPCHealth(server *grpc.Server) Option {
+	return func(stream *health.Stream) {
+		sink := newGRPCHealthSink()
+		stream.AddSink(sink)
+		grpc_health_v1.RegisterHealthServer(server, sink)
+	}
+}
+
+func (s *grpcHealthSink) EmitEvent(job string, event string, kvs map[string]string) {}
+
+func (s *grpcHealthSink) EmitEventErr(job string, event string, inputErr error, kvs map[string]string) {
+}
+
+func (s *grpcHealthSink) EmitTiming(job string, event string, nanoseconds int64, kvs map[string]string) {
+}
+
+func (s *grpcHealthSink) EmitGauge(job string, event string, value float64, kvs map[string]string) {}
+
+func (s *grpcHealthSink) EmitComplete(job string, status health.CompletionStatus, nanoseconds int64, kvs map[string]string) {
+	isError := status == health.Error || status == health.Panic
+	now := time.Now().Unix()
+
+	s.mu.Lock()
+	b := &s.buckets[now%int64(numBuckets)]
+	if b.secondsSinceEpoch != now {
+		*b = bucket{secondsSinceEpoch: now}
+	}
+	b.total++
+	if isError {
+		b.errors++
+	}
+	current := s.statusLocked(now)
+	transitioned := current != s.lastBroadcast
+	s.lastBroadcast = current
+	s.mu.Unlock()
+
+	// Watch is a stream of transitions, not a heartbeat - a Watcher
+	// polling on every single completion at request volume would
+	// defeat the point of pushing updates at all.
+	if transitioned {
+		s.broadcast()
+	}
+}
+
+// status reports the current serving status.
+func (s *grpcHealthSink) status() grpc_health_v1.HealthCheckResponse_ServingStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statusLocked(time.Now().Unix())
+}
+
+// statusLocked computes the serving status as of nowUnix; callers
+// must hold s.mu.
+func (s *grpcHealthSink) statusLocked(nowUnix int64) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	cutoff := nowUnix - int64(errorRateWindow/time.Second)
+
+	var total, errors int64
+	for _, b := range s.buckets {
+		if b.secondsSinceEpoch > cutoff {
+			total += b.total
+			errors += b.errors
+		}
+	}
+
+	if total == 0 {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	if float64(errors)/float64(total) > unhealthyErrorRate {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// broadcast wakes every active Watch stream, not just one of them.
+func (s *grpcHealthSink) broadcast() {
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+
+	for ch := range s.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *grpcHealthSink) addWatcher() chan struct{} {
+	ch := make(chan struct{}, 1)
+	s.watchersMu.Lock()
+	s.watchers[ch] = struct{}{}
+	s.watchersMu.Unlock()
+	return ch
+}
+
+func (s *grpcHealthSink) removeWatcher(ch chan struct{}) {
+	s.watchersMu.Lock()
+	delete(s.watchers, ch)
+	s.watchersMu.Unlock()
+}
+
+// Check implements grpc_health_v1.HealthServer.
+func (s *grpcHealthSink) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return &grpc_health_v1.HealthCheckResponse{Status: s.status()}, nil
+}
+
+// Watch implements grpc_health_v1.HealthServer, streaming the status
+// to every active watcher whenever a completion makes it transition.
+// A transition caused only by old completions leaving the window is
+// not sent until the next completion arrives.
+func (s *grpcHealthSink) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	updates := s.addWatcher()
+	defer s.removeWatcher(updates)
+
+	if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: s.status()}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-updates:
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: s.status()}); err != nil {
+				return err
+			}
+		}
+	}
+}
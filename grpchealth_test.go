@@ -0,0 +1,61 @@
+package ghealth
+
+import (
+	"testing"
+
+	"github.com/gocraft/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCHealthSinkStatus(t *testing.T) {
+	s := newGRPCHealthSink()
+
+	if got := s.status(); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("status() with no completions = %v, want SERVING", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		s.EmitComplete("job", health.Success, 0, nil)
+	}
+	if got := s.status(); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("status() with all successes = %v, want SERVING", got)
+	}
+
+	for i := 0; i < 20; i++ {
+		s.EmitComplete("job", health.Error, 0, nil)
+	}
+	if got := s.status(); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status() with majority errors = %v, want NOT_SERVING", got)
+	}
+}
+
+func TestGRPCHealthSinkBroadcastsOnlyOnTransition(t *testing.T) {
+	s := newGRPCHealthSink()
+	ch := s.addWatcher()
+	defer s.removeWatcher(ch)
+
+	// Repeated successes don't change SERVING -> SERVING: no broadcast.
+	for i := 0; i < 5; i++ {
+		s.EmitComplete("job", health.Success, 0, nil)
+	}
+	select {
+	case <-ch:
+		t.Fatal("got a broadcast for a non-transitioning status")
+	default:
+	}
+
+	// Enough errors to flip to NOT_SERVING must broadcast exactly once.
+	for i := 0; i < 6; i++ {
+		s.EmitComplete("job", health.Error, 0, nil)
+	}
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected a broadcast on SERVING -> NOT_SERVING transition")
+	}
+	select {
+	case <-ch:
+		t.Fatal("got more than one broadcast for a single transition")
+	default:
+	}
+}
@@ -0,0 +1,287 @@
+package healthd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// wireAgentResponse mirrors the JSON object served by a
+// health.JsonPollingSink, decoded independently of gocraft/health's
+// in-memory types - as gocraft/healthd itself does - so a change in
+// the library's exported struct layout doesn't break compilation.
+// Fields missing from the payload decode as zero values without an
+// error.
+type wireAgentResponse struct {
+	InstanceId       string                    `json:"InstanceId"`
+	IntervalDuration time.Duration             `json:"IntervalDuration"`
+	Aggregations     []wireIntervalAggregation `json:"Aggregations"`
+}
+
+// wireIntervalAggregation mirrors health.IntervalAggregation.
+type wireIntervalAggregation struct {
+	IntervalStart time.Time                     `json:"IntervalStart"`
+	Jobs          map[string]wireJobAggregation `json:"Jobs"`
+}
+
+// wireJobAggregation mirrors health.JobAggregation: completion counts
+// are split by status (only CountError, CountValidationError and
+// CountPanic count as failures - CountPanic is distinct from
+// CountError), and timing is nested under the job's TimerAggregation.
+type wireJobAggregation struct {
+	Count                int64                 `json:"Count"`
+	CountError           int64                 `json:"CountError"`
+	CountValidationError int64                 `json:"CountValidationError"`
+	CountPanic           int64                 `json:"CountPanic"`
+	TimerAggregation     *wireTimerAggregation `json:"TimerAggregation"`
+}
+
+// wireTimerAggregation mirrors health.TimerAggregation.
+type wireTimerAggregation struct {
+	NanosSum int64 `json:"NanosSum"`
+}
+
+// sample is one job's stats for one interval, pulled out of a
+// wireIntervalAggregation.Jobs map and stamped with when the
+// aggregator fetched it so it can be pruned out of rolling windows
+// once it ages out.
+type sample struct {
+	at         time.Time
+	job        string
+	count      int64
+	errorCount int64
+	nanosSum   int64
+}
+
+// JobStats summarizes the samples for a single job name over a
+// window.
+type JobStats struct {
+	Job         string `json:"job"`
+	Count       int64  `json:"count"`
+	ErrorCount  int64  `json:"error_count"`
+	NanosSum    int64  `json:"nanos_sum"`
+	NanosPerHit int64  `json:"nanos_per_hit"`
+}
+
+// Aggregator polls itself and its peers' JSON polling sinks on a
+// tick and keeps a rolling window of the IntervalAggregations seen,
+// so it can answer "slowest endpoints", "top error producers" and
+// "throughput leaders" queries without a separate healthd process.
+type Aggregator struct {
+	cfg    *config
+	client *http.Client
+
+	mu      sync.Mutex
+	samples []sample
+	// seen tracks, per source address, the IntervalStart of every
+	// IntervalAggregation already ingested from it - a sink keeps
+	// serving its recent intervals on every poll, so without this a
+	// tick shorter than an interval would sum the same interval in
+	// repeatedly.
+	seen map[string]map[time.Time]time.Time
+
+	stop chan struct{}
+}
+
+func newAggregator(cfg *config) *Aggregator {
+	return &Aggregator{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.pollEvery},
+		seen:   make(map[string]map[time.Time]time.Time),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start begins polling in the background. Mount calls this for you.
+func (a *Aggregator) Start() {
+	go a.run()
+}
+
+// Stop ends the background polling loop.
+func (a *Aggregator) Stop() {
+	close(a.stop)
+}
+
+func (a *Aggregator) run() {
+	a.poll()
+	ticker := time.NewTicker(a.cfg.pollEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+			a.poll()
+		}
+	}
+}
+
+func (a *Aggregator) poll() {
+	now := time.Now()
+	for _, addr := range a.cfg.sources() {
+		aggs, err := a.fetch(addr)
+		if err != nil {
+			continue
+		}
+		a.ingest(addr, now, aggs)
+	}
+	a.prune(now)
+}
+
+func (a *Aggregator) fetch(addr string) ([]wireIntervalAggregation, error) {
+	url := fmt.Sprintf("%s://%s/", a.cfg.httpScheme, addr)
+	resp, err := a.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body wireAgentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Aggregations, nil
+}
+
+// ingest flattens the not-yet-seen IntervalAggregations fetched from
+// addr into per-job samples.
+func (a *Aggregator) ingest(addr string, now time.Time, aggs []wireIntervalAggregation) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seen := a.seen[addr]
+	if seen == nil {
+		seen = make(map[time.Time]time.Time)
+		a.seen[addr] = seen
+	}
+
+	for _, agg := range aggs {
+		if _, ok := seen[agg.IntervalStart]; ok {
+			continue
+		}
+		seen[agg.IntervalStart] = now
+
+		for name, job := range agg.Jobs {
+			var nanosSum int64
+			if job.TimerAggregation != nil {
+				nanosSum = job.TimerAggregation.NanosSum
+			}
+			a.samples = append(a.samples, sample{
+				at:         now,
+				job:        name,
+				count:      job.Count,
+				errorCount: job.CountError + job.CountValidationError + job.CountPanic,
+				nanosSum:   nanosSum,
+			})
+		}
+	}
+}
+
+// prune drops samples, and seen-interval bookkeeping, older than the
+// widest rolling window.
+func (a *Aggregator) prune(now time.Time) {
+	cutoff := now.Add(-Windows[len(Windows)-1])
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	kept := a.samples[:0]
+	for _, s := range a.samples {
+		if s.at.After(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	a.samples = kept
+
+	for addr, seen := range a.seen {
+		for interval, seenAt := range seen {
+			if seenAt.Before(cutoff) {
+				delete(seen, interval)
+			}
+		}
+		if len(seen) == 0 {
+			delete(a.seen, addr)
+		}
+	}
+}
+
+// Aggregations returns per-job stats for each rolling window, keyed
+// by window name ("1m", "5m", "1h", "24h").
+func (a *Aggregator) Aggregations() map[string][]JobStats {
+	out := make(map[string][]JobStats, len(Windows))
+	for _, w := range Windows {
+		out[windowName(w)] = a.aggregate(w)
+	}
+	return out
+}
+
+// JobNames returns the distinct job names seen within the widest
+// rolling window.
+func (a *Aggregator) JobNames() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seen := map[string]struct{}{}
+	for _, s := range a.samples {
+		seen[s.job] = struct{}{}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Hosts returns the configured self/peer addresses the aggregator
+// polls.
+func (a *Aggregator) Hosts() []string {
+	return a.cfg.sources()
+}
+
+func (a *Aggregator) aggregate(window time.Duration) []JobStats {
+	cutoff := time.Now().Add(-window)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	byJob := map[string]*JobStats{}
+	for _, s := range a.samples {
+		if s.at.Before(cutoff) {
+			continue
+		}
+		js, ok := byJob[s.job]
+		if !ok {
+			js = &JobStats{Job: s.job}
+			byJob[s.job] = js
+		}
+		js.Count += s.count
+		js.ErrorCount += s.errorCount
+		js.NanosSum += s.nanosSum
+	}
+
+	stats := make([]JobStats, 0, len(byJob))
+	for _, js := range byJob {
+		if js.Count > 0 {
+			js.NanosPerHit = js.NanosSum / js.Count
+		}
+		stats = append(stats, *js)
+	}
+	return stats
+}
+
+func windowName(d time.Duration) string {
+	switch d {
+	case time.Minute:
+		return "1m"
+	case 5 * time.Minute:
+		return "5m"
+	case time.Hour:
+		return "1h"
+	case 24 * time.Hour:
+		return "24h"
+	default:
+		return d.String()
+	}
+}
@@ -0,0 +1,68 @@
+package healthd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregatorIngestFromWireFormat(t *testing.T) {
+	intervalStart := time.Now().Add(-time.Minute).Truncate(time.Second)
+	payload := fmt.Sprintf(`{
+		"InstanceId": "instance-1",
+		"IntervalDuration": 60000000000,
+		"Aggregations": [{
+			"IntervalStart": %q,
+			"Jobs": {
+				"GET /users/:id": {
+					"Count": 10,
+					"CountError": 1,
+					"CountValidationError": 2,
+					"CountPanic": 0,
+					"TimerAggregation": {"NanosSum": 500000000}
+				}
+			}
+		}]
+	}`, intervalStart.Format(time.RFC3339Nano))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	cfg := defaultConfig()
+	cfg.self = addr
+	a := newAggregator(cfg)
+
+	aggs, err := a.fetch(addr)
+	if err != nil {
+		t.Fatalf("fetch() error: %v", err)
+	}
+
+	now := time.Now()
+	a.ingest(addr, now, aggs)
+	a.ingest(addr, now, aggs) // repeat poll of the same interval must not double-count
+
+	stats := a.aggregate(time.Hour)
+	if len(stats) != 1 {
+		t.Fatalf("got %d job stats, want 1", len(stats))
+	}
+
+	js := stats[0]
+	if js.Job != "GET /users/:id" {
+		t.Errorf("Job = %q, want %q", js.Job, "GET /users/:id")
+	}
+	if js.Count != 10 {
+		t.Errorf("Count = %d, want 10", js.Count)
+	}
+	if js.ErrorCount != 3 {
+		t.Errorf("ErrorCount = %d, want 3 (CountError + CountValidationError + CountPanic)", js.ErrorCount)
+	}
+	if js.NanosSum != 500000000 {
+		t.Errorf("NanosSum = %d, want 500000000", js.NanosSum)
+	}
+}
@@ -0,0 +1,29 @@
+/*
+Package healthd embeds a gocraft/healthd-like aggregator directly in
+the gin process, so a service doesn't need to run a separate healthd
+binary to get a dashboard over its gin-health jobs.
+
+It polls a health.JsonPollingSink's JSON endpoint - its own, and
+optionally a list of peer host:ports running the same sink - on a
+tick, and merges the IntervalAggregations it finds into rolling 1m,
+5m, 1h and 24h buckets keyed by job name. Mount() wires up
+/aggregations, /jobs and /hosts JSON routes plus an HTML status page
+on any *gin.RouterGroup:
+
+	rg := router.Group("/healthd")
+	healthd.Mount(rg,
+		healthd.WithSelf("127.0.0.1:5020"),
+		healthd.WithPeers("10.0.0.2:5020", "10.0.0.3:5020"),
+	)
+*/
+package healthd
+
+import "time"
+
+// Windows are the rolling buckets the aggregator keeps per job name.
+var Windows = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	time.Hour,
+	24 * time.Hour,
+}
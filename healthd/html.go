@@ -0,0 +1,85 @@
+package healthd
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusPageWindow is the rolling window the HTML status page
+// summarizes.
+const statusPageWindow = "5m"
+
+var statusPageTemplate = template.Must(template.New("healthd").Parse(`
+<!DOCTYPE html>
+<html>
+<head><title>gin-health</title></head>
+<body>
+<h1>gin-health - last {{.Window}}</h1>
+
+<h2>Slowest endpoints</h2>
+<table border="1" cellpadding="4">
+<tr><th>Job</th><th>ns/hit</th><th>Count</th></tr>
+{{range .Slowest}}<tr><td>{{.Job}}</td><td>{{.NanosPerHit}}</td><td>{{.Count}}</td></tr>
+{{end}}
+</table>
+
+<h2>Top error producers</h2>
+<table border="1" cellpadding="4">
+<tr><th>Job</th><th>Errors</th><th>Count</th></tr>
+{{range .TopErrors}}<tr><td>{{.Job}}</td><td>{{.ErrorCount}}</td><td>{{.Count}}</td></tr>
+{{end}}
+</table>
+
+<h2>Throughput leaders</h2>
+<table border="1" cellpadding="4">
+<tr><th>Job</th><th>Count</th></tr>
+{{range .Busiest}}<tr><td>{{.Job}}</td><td>{{.Count}}</td></tr>
+{{end}}
+</table>
+
+</body>
+</html>
+`))
+
+type statusPageData struct {
+	Window    string
+	Slowest   []JobStats
+	TopErrors []JobStats
+	Busiest   []JobStats
+}
+
+func statusPageHandler(agg *Aggregator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats := agg.Aggregations()[statusPageWindow]
+
+		slowest := append([]JobStats{}, stats...)
+		sort.Slice(slowest, func(i, j int) bool { return slowest[i].NanosPerHit > slowest[j].NanosPerHit })
+
+		topErrors := append([]JobStats{}, stats...)
+		sort.Slice(topErrors, func(i, j int) bool { return topErrors[i].ErrorCount > topErrors[j].ErrorCount })
+
+		busiest := append([]JobStats{}, stats...)
+		sort.Slice(busiest, func(i, j int) bool { return busiest[i].Count > busiest[j].Count })
+
+		data := statusPageData{
+			Window:    statusPageWindow,
+			Slowest:   topN(slowest, 10),
+			TopErrors: topN(topErrors, 10),
+			Busiest:   topN(busiest, 10),
+		}
+
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		_ = statusPageTemplate.Execute(c.Writer, data)
+	}
+}
+
+func topN(stats []JobStats, n int) []JobStats {
+	if len(stats) < n {
+		return stats
+	}
+	return stats[:n]
+}
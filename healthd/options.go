@@ -0,0 +1,67 @@
+package healthd
+
+import "time"
+
+// defaultPollInterval is how often the aggregator re-polls itself
+// and its peers when no WithPollInterval is given.
+const defaultPollInterval = 10 * time.Second
+
+// config holds the settings built up by Options.
+type config struct {
+	self       string
+	peers      []string
+	pollEvery  time.Duration
+	httpScheme string
+}
+
+// Option configures the aggregator built by Mount.
+type Option func(*config)
+
+func defaultConfig() *config {
+	return &config{
+		pollEvery:  defaultPollInterval,
+		httpScheme: "http",
+	}
+}
+
+// WithSelf points the aggregator at the host:port of the
+// health.JsonPollingSink running in this same process, e.g. the
+// serversink address passed to WithJSONPolling.
+func WithSelf(addr string) Option {
+	return func(c *config) {
+		c.self = addr
+	}
+}
+
+// WithPeers adds host:port addresses of other instances' JSON
+// polling sinks, so a multi-instance deployment can aggregate
+// across pods.
+func WithPeers(addrs ...string) Option {
+	return func(c *config) {
+		c.peers = append(c.peers, addrs...)
+	}
+}
+
+// WithPollInterval overrides how often the aggregator polls itself
+// and its peers. Defaults to 10 seconds.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.pollEvery = d
+	}
+}
+
+// WithHTTPScheme overrides the scheme used to reach self/peers.
+// Defaults to "http".
+func WithHTTPScheme(scheme string) Option {
+	return func(c *config) {
+		c.httpScheme = scheme
+	}
+}
+
+func (c *config) sources() []string {
+	sources := make([]string, 0, len(c.peers)+1)
+	if c.self != "" {
+		sources = append(sources, c.self)
+	}
+	return append(sources, c.peers...)
+}
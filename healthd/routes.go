@@ -0,0 +1,34 @@
+package healthd
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Mount wires up /aggregations, /jobs and /hosts JSON routes plus an
+// HTML status page onto rg, and starts the aggregator polling in the
+// background. The returned *Aggregator can be stopped with Stop.
+func Mount(rg *gin.RouterGroup, opts ...Option) *Aggregator {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	agg := newAggregator(cfg)
+	agg.Start()
+
+	rg.GET("/aggregations", func(c *gin.Context) {
+		c.JSON(http.StatusOK, agg.Aggregations())
+	})
+	rg.GET("/jobs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, agg.JobNames())
+	})
+	rg.GET("/hosts", func(c *gin.Context) {
+		c.JSON(http.StatusOK, agg.Hosts())
+	})
+	rg.GET("", statusPageHandler(agg))
+	rg.GET("/", statusPageHandler(agg))
+
+	return agg
+}
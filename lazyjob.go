@@ -0,0 +1,34 @@
+package ghealth
+
+import (
+	"sync"
+
+	"github.com/gocraft/health"
+)
+
+// lazyJob defers stream.NewJob until the job is actually needed -
+// either because a handler asked for it via Job(c), or because
+// sampling decided the request should be reported, or because
+// Health() pre-allocated it up front (see Health()'s tail-sampling
+// case, which needs the job's start time to be the real request
+// start) - so a sampled-out request with no tail sampler configured
+// never allocates a *health.Job at all.
+type lazyJob struct {
+	mu     sync.Mutex
+	job    *health.Job
+	stream *health.Stream
+	name   string
+}
+
+// resolve returns the job, allocating it via stream.NewJob on first
+// use. Safe to call more than once and from more than one place
+// (Health()'s defer, a handler's Job(c)) - only the first call
+// allocates.
+func (l *lazyJob) resolve() *health.Job {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.job == nil {
+		l.job = l.stream.NewJob(l.name)
+	}
+	return l.job
+}
@@ -0,0 +1,32 @@
+package ghealth
+
+import "github.com/gin-gonic/gin"
+
+// JobNamer derives a job name for a request. The default
+// implementation, DefaultJobNamer, uses the matched route template
+// (c.FullPath()) rather than the raw request URI, so dynamic path
+// segments of matched routes don't blow up cardinality. A request that
+// matched no route is named after its URL path instead.
+type JobNamer interface {
+	JobName(c *gin.Context) string
+}
+
+// JobNamerFunc adapts a plain function to JobNamer.
+type JobNamerFunc func(c *gin.Context) string
+
+// JobName implements JobNamer.
+func (f JobNamerFunc) JobName(c *gin.Context) string {
+	return f(c)
+}
+
+// DefaultJobNamer names jobs "<method> <route template>", e.g.
+// "GET /users/:id", or "<method> <URL path>" when no route matched.
+var DefaultJobNamer JobNamer = JobNamerFunc(routeName)
+
+// WithJobNamer overrides how Health() derives a job name from the
+// request. Defaults to DefaultJobNamer.
+func WithJobNamer(namer JobNamer) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.jobNamer = namer
+	}
+}
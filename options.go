@@ -0,0 +1,98 @@
+package ghealth
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gocraft/health"
+)
+
+// Option configures the *health.Stream built by NewStreamWithOptions.
+type Option func(*health.Stream)
+
+// NewStreamWithOptions builds a *health.Stream out of the given
+// Options, e.g. WithStatsD, WithJSONPolling, WithWriter, WithSyslog,
+// WithBugsnag and WithGRPCHealth. Options are applied in order, so
+// later options can add sinks on top of earlier ones.
+func NewStreamWithOptions(opts ...Option) *health.Stream {
+	stream := health.NewStream()
+	for _, opt := range opts {
+		opt(stream)
+	}
+	return stream
+}
+
+// WithStatsD adds a StatsD sink using the supplied address (IP:PORT)
+// and appname. If the sink can't be created, the error is reported
+// on the stream itself instead of failing NewStreamWithOptions.
+func WithStatsD(addr string, appname string) Option {
+	return func(stream *health.Stream) {
+		sink, err := health.NewStatsDSink(addr, appname)
+		if err != nil {
+			stream.EventErr("new_statsd_sink", err)
+			return
+		}
+		stream.AddSink(sink)
+	}
+}
+
+// WithWriter adds a sink that writes human-readable job events to w,
+// e.g. os.Stdout.
+func WithWriter(w io.Writer) Option {
+	return func(stream *health.Stream) {
+		stream.AddSink(&health.WriterSink{w})
+	}
+}
+
+// WithJSONPolling adds a health.JsonPollingSink and starts its HTTP
+// server on addr, so healthd (or ghealth/healthd) can poll it.
+func WithJSONPolling(addr string) Option {
+	return func(stream *health.Stream) {
+		sink := health.NewJsonPollingSink(time.Minute, time.Minute*5)
+		stream.AddSink(sink)
+		sink.StartServer(addr)
+	}
+}
+
+// NewStream initializes health sink to statsd using supplied
+// statsd address (IP:PORT) and appname.
+// Falls back to stdout if none supplied.
+// Also creates Json sink for healthd at supplied address
+// (serversink) if not empty.
+//
+// statsd: StatsD address and port.
+//
+// appname Application name for StatsD.
+//
+// serversink: Bind address for Json sink, empty if not needed.
+//
+// Deprecated: kept as a thin shim over NewStreamWithOptions for one
+// release; switch to NewStreamWithOptions with
+// WithStatsD/WithJSONPolling/WithWriter instead.
+func NewStream(statsd string, appname string, serversink string) *health.Stream {
+	stream := NewStreamWithOptions()
+
+	if statsd != "" {
+		sink, err := health.NewStatsDSink(statsd, appname)
+		if err != nil {
+			fmt.Println("HEALTH: Adding stdout health sink...")
+			WithWriter(os.Stdout)(stream)
+			stream.EventErr("new_statsd_sink", err)
+		} else {
+			fmt.Println("HEALTH: Adding statsd health sink...")
+			stream.AddSink(sink)
+		}
+	} else {
+		fmt.Println("HEALTH: Adding stdout health sink...")
+		WithWriter(os.Stdout)(stream)
+	}
+
+	if serversink != "" {
+		fmt.Println("HEALTH: Adding json health sink...")
+		WithJSONPolling(serversink)(stream)
+	}
+
+	return stream
+}
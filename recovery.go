@@ -0,0 +1,102 @@
+package ghealth
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRequestIDHeader is the header read for the request_id kv
+// pair attached to panic events, unless overridden with
+// WithRequestIDHeader.
+const defaultRequestIDHeader = "X-Request-Id"
+
+// panicStackSize bounds the runtime.Stack snapshot taken on panic.
+const panicStackSize = 8192
+
+// PanicConverter turns a recovered panic value into an error.
+type PanicConverter func(rval interface{}) error
+
+// KvExtractor builds the kv pairs attached to the "panic" event
+// reported to the stream.
+type KvExtractor func(c *gin.Context, err error) map[string]string
+
+// HealthOption configures Health(): its recovery behaviour, job
+// naming, sampling and job-name cardinality cap.
+type HealthOption func(*healthConfig)
+
+type healthConfig struct {
+	panicConverter  PanicConverter
+	kvExtractor     KvExtractor
+	requestIDHeader string
+
+	jobNamer    JobNamer
+	samplers    []Sampler
+	tailSampler TailSampler
+	maxJobNames int
+}
+
+func defaultHealthConfig() *healthConfig {
+	return &healthConfig{
+		panicConverter:  defaultPanicConverter,
+		requestIDHeader: defaultRequestIDHeader,
+		jobNamer:        DefaultJobNamer,
+		maxJobNames:     defaultMaxJobNames,
+	}
+}
+
+// defaultPanicConverter passes errors through unchanged and wraps
+// anything else (strings, runtime.Error, ...) with fmt.Errorf, so a
+// non-error panic value never panics the recovery itself.
+func defaultPanicConverter(rval interface{}) error {
+	if err, ok := rval.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", rval)
+}
+
+// defaultKvExtractor attaches the request's method, matched route,
+// client IP, request ID (read from requestIDHeader) and a captured
+// stack trace to the panic event.
+func defaultKvExtractor(requestIDHeader string) KvExtractor {
+	return func(c *gin.Context, err error) map[string]string {
+		buf := make([]byte, panicStackSize)
+		n := runtime.Stack(buf, false)
+
+		return map[string]string{
+			"method":     c.Request.Method,
+			"route":      routeName(c),
+			"client_ip":  c.ClientIP(),
+			"request_id": c.GetHeader(requestIDHeader),
+			"stack":      string(buf[:n]),
+		}
+	}
+}
+
+// WithPanicConverter overrides how a recovered panic value is turned
+// into the error reported to the stream. Defaults to passing errors
+// through unchanged and wrapping anything else with fmt.Errorf.
+func WithPanicConverter(f PanicConverter) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.panicConverter = f
+	}
+}
+
+// WithKvExtractor overrides which kv pairs are attached to the
+// "panic" event. Defaults to method, route, client IP, request ID and
+// a captured stack trace.
+func WithKvExtractor(f KvExtractor) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.kvExtractor = f
+	}
+}
+
+// WithRequestIDHeader overrides which request header is read for the
+// request_id kv pair attached to panic events by the default
+// KvExtractor. Defaults to "X-Request-Id".
+func WithRequestIDHeader(header string) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.requestIDHeader = header
+	}
+}
@@ -0,0 +1,38 @@
+package ghealth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDefaultPanicConverter(t *testing.T) {
+	errPanic := errors.New("boom")
+
+	tests := []struct {
+		name string
+		rval interface{}
+		want error
+	}{
+		{"error passed through unchanged", errPanic, errPanic},
+		{"string wrapped", "boom", errors.New("boom")},
+		{"non-error wrapped", 42, errors.New("42")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := defaultPanicConverter(tt.rval)
+			if got == nil {
+				t.Fatal("defaultPanicConverter() = nil")
+			}
+			if tt.name == "error passed through unchanged" {
+				if got != tt.want {
+					t.Errorf("defaultPanicConverter() = %v, want the same error passed through", got)
+				}
+				return
+			}
+			if got.Error() != tt.want.Error() {
+				t.Errorf("defaultPanicConverter() = %q, want %q", got.Error(), tt.want.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package ghealth
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocraft/health"
+)
+
+// Sampler decides, before a request runs, whether its job should be
+// reported to the stream at all.
+type Sampler interface {
+	Sample(c *gin.Context) bool
+}
+
+// SamplerFunc adapts a plain function to Sampler.
+type SamplerFunc func(c *gin.Context) bool
+
+// Sample implements Sampler.
+func (f SamplerFunc) Sample(c *gin.Context) bool {
+	return f(c)
+}
+
+// TailSampler can overturn a head-sampled-out decision once the
+// request's outcome is known.
+type TailSampler interface {
+	SampleTail(status health.CompletionStatus, duration time.Duration) bool
+}
+
+// TailSamplerFunc adapts a plain function to TailSampler.
+type TailSamplerFunc func(status health.CompletionStatus, duration time.Duration) bool
+
+// SampleTail implements TailSampler.
+func (f TailSamplerFunc) SampleTail(status health.CompletionStatus, duration time.Duration) bool {
+	return f(status, duration)
+}
+
+// WithSampleRate head-samples requests: only a `rate` fraction (0..1)
+// of them are reported to the stream. Combines with other sampling
+// options: a request is only reported if every one of them agrees.
+func WithSampleRate(rate float64) HealthOption {
+	return addSampler(SamplerFunc(func(c *gin.Context) bool {
+		return rand.Float64() < rate
+	}))
+}
+
+// WithRouteAllowList only reports requests whose matched route
+// template is in routes. Combines with other sampling options: a
+// request is only reported if every one of them agrees.
+func WithRouteAllowList(routes ...string) HealthOption {
+	allow := routeSet(routes)
+	return addSampler(SamplerFunc(func(c *gin.Context) bool {
+		_, ok := allow[c.FullPath()]
+		return ok
+	}))
+}
+
+// WithRouteDenyList never reports requests whose matched route
+// template is in routes. Combines with other sampling options: a
+// request is only reported if every one of them agrees.
+func WithRouteDenyList(routes ...string) HealthOption {
+	deny := routeSet(routes)
+	return addSampler(SamplerFunc(func(c *gin.Context) bool {
+		_, ok := deny[c.FullPath()]
+		return !ok
+	}))
+}
+
+// addSampler appends a Sampler that must agree, alongside any other
+// samplers already configured, for a request to be reported.
+func addSampler(s Sampler) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.samplers = append(cfg.samplers, s)
+	}
+}
+
+func routeSet(routes []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(routes))
+	for _, r := range routes {
+		set[r] = struct{}{}
+	}
+	return set
+}
+
+// WithTailSampling overrides a head-sampled-out decision: requests
+// that errored (if errorsAlways) or took longer than slowerThan are
+// always reported, even if WithSampleRate/allow-deny lists would have
+// dropped them.
+func WithTailSampling(errorsAlways bool, slowerThan time.Duration) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.tailSampler = TailSamplerFunc(func(status health.CompletionStatus, duration time.Duration) bool {
+			if errorsAlways && (status == health.Error || status == health.ValidationError) {
+				return true
+			}
+			if slowerThan > 0 && duration > slowerThan {
+				return true
+			}
+			return false
+		})
+	}
+}
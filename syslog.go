@@ -0,0 +1,51 @@
+package ghealth
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"github.com/gocraft/health"
+)
+
+// syslogSink is a health.Sink that forwards job events to a
+// log/syslog.Writer, one line per event.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// WithSyslog adds a sink that forwards job events to the local
+// syslog daemon, tagged with the given facility and tag.
+func WithSyslog(priority syslog.Priority, tag string) Option {
+	return func(stream *health.Stream) {
+		w, err := syslog.New(priority, tag)
+		if err != nil {
+			stream.EventErr("new_syslog_sink", err)
+			return
+		}
+		stream.AddSink(&syslogSink{w: w})
+	}
+}
+
+func (s *syslogSink) EmitEvent(job string, event string, kvs map[string]string) {
+	s.w.Info(fmt.Sprintf("job=%s event=%s kvs=%v", job, event, kvs))
+}
+
+func (s *syslogSink) EmitEventErr(job string, event string, inputErr error, kvs map[string]string) {
+	s.w.Err(fmt.Sprintf("job=%s event=%s err=%v kvs=%v", job, event, inputErr, kvs))
+}
+
+func (s *syslogSink) EmitTiming(job string, event string, nanoseconds int64, kvs map[string]string) {
+	s.w.Info(fmt.Sprintf("job=%s event=%s timing=%dns kvs=%v", job, event, nanoseconds, kvs))
+}
+
+func (s *syslogSink) EmitGauge(job string, event string, value float64, kvs map[string]string) {
+	s.w.Info(fmt.Sprintf("job=%s event=%s gauge=%v kvs=%v", job, event, value, kvs))
+}
+
+func (s *syslogSink) EmitComplete(job string, status health.CompletionStatus, nanoseconds int64, kvs map[string]string) {
+	if status == health.Error || status == health.Panic {
+		s.w.Err(fmt.Sprintf("job=%s status=%v timing=%dns kvs=%v", job, status, nanoseconds, kvs))
+		return
+	}
+	s.w.Info(fmt.Sprintf("job=%s status=%v timing=%dns kvs=%v", job, status, nanoseconds, kvs))
+}